@@ -0,0 +1,217 @@
+package qra
+
+import (
+	"bytes"
+	"strings"
+)
+
+// wildcard, as an element of a PolicyRule field, matches any value of
+// the corresponding Attributes field. A "*" in Attributes is compared
+// literally.
+const wildcard = "*"
+
+// Decision is the outcome of an authorization check performed by an
+// Authorizer or Evaluator.
+type Decision int
+
+const (
+	// DecisionNoOpinion means the check did not find a rule that
+	// speaks to the request; callers should consult the next
+	// Authorizer/Evaluator, or deny by default if there are none left.
+	DecisionNoOpinion Decision = iota
+
+	// DecisionAllow means the request is authorized.
+	DecisionAllow
+
+	// DecisionDeny means the request is explicitly forbidden.
+	DecisionDeny
+)
+
+// PolicyRule grants the verbs in Verbs over the resources in
+// Resources (optionally narrowed to ResourceNames), within the API
+// groups in APIGroups, or over the non-resource URLs in
+// NonResourceURLs. Use "*" as an element to match any value of a
+// field. Verbs, Resources and NonResourceURLs must be matched
+// explicitly: a zero-value slice for one of these never matches.
+// APIGroups and ResourceNames instead scope a rule that already
+// matched on Verbs/Resources; a zero-value slice for one of these
+// means the rule is unscoped by that field and matches any value,
+// see APIGroupMatches and ResourceNameMatches.
+type PolicyRule struct {
+	Verbs           []string
+	APIGroups       []string
+	Resources       []string
+	ResourceNames   []string
+	NonResourceURLs []string
+}
+
+// Attributes describes the request being authorized.
+type Attributes struct {
+	Verb         string
+	APIGroup     string
+	Resource     string
+	ResourceName string
+
+	// NonResourceURL, when set, identifies a request against a path
+	// that isn't backed by a Resource (e.g. "/healthz"). Rule matching
+	// for this case is done with NonResourceURLMatches, not RuleAllows.
+	NonResourceURL string
+}
+
+// Authorizer decides whether ctx may perform the request described by
+// attrs. It returns DecisionNoOpinion, together with a human-readable
+// reason, when it has no rule applicable to the request.
+type Authorizer interface {
+	Authorize(ctx Identity, attrs Attributes) (Decision, string, error)
+}
+
+// VerbMatches reports whether rule grants verb, treating "*" in
+// rule.Verbs as matching any verb. It short-circuits on the first
+// match.
+func VerbMatches(rule *PolicyRule, verb string) bool {
+	return matchesAny(rule.Verbs, verb)
+}
+
+// APIGroupMatches reports whether rule grants access within group,
+// treating "*" in rule.APIGroups as matching any group. A rule with an
+// empty APIGroups list is not scoped to any particular group, so it is
+// treated as a match here, mirroring ResourceNameMatches.
+func APIGroupMatches(rule *PolicyRule, group string) bool {
+	if len(rule.APIGroups) == 0 {
+		return true
+	}
+	return matchesAny(rule.APIGroups, group)
+}
+
+// ResourceMatches reports whether rule grants access to resource,
+// treating "*" in rule.Resources as matching any resource.
+func ResourceMatches(rule *PolicyRule, resource string) bool {
+	return matchesAny(rule.Resources, resource)
+}
+
+// ResourceNameMatches reports whether rule grants access to name. A
+// rule with an empty ResourceNames list applies to every name of the
+// resources it grants, which is why it is treated as a match here.
+func ResourceNameMatches(rule *PolicyRule, name string) bool {
+	if len(rule.ResourceNames) == 0 {
+		return true
+	}
+	return matchesAny(rule.ResourceNames, name)
+}
+
+// NonResourceURLMatches reports whether rule grants access to url,
+// treating "*" in rule.NonResourceURLs as matching any URL.
+func NonResourceURLMatches(rule *PolicyRule, url string) bool {
+	return matchesAny(rule.NonResourceURLs, url)
+}
+
+// RuleAllows reports whether rule grants the resource request
+// described by attrs, ANDing VerbMatches, APIGroupMatches,
+// ResourceMatches and ResourceNameMatches. It does not consider
+// NonResourceURLs; use NonResourceURLMatches for non-resource
+// requests.
+func RuleAllows(rule *PolicyRule, attrs Attributes) bool {
+	return VerbMatches(rule, attrs.Verb) &&
+		APIGroupMatches(rule, attrs.APIGroup) &&
+		ResourceMatches(rule, attrs.Resource) &&
+		ResourceNameMatches(rule, attrs.ResourceName)
+}
+
+func matchesAny(values []string, want string) bool {
+	for _, v := range values {
+		if v == wildcard || v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleSearcher is an optional extension of Designation, implemented by
+// Designations that can emit PolicyRules directly. RuleResolver
+// prefers it over parsing Search's permission:resource strings.
+type RuleSearcher interface {
+	SearchRules(ctx Identity, filter string) ([]*PolicyRule, error)
+}
+
+// RuleResolver computes the union of PolicyRules applicable to an
+// identity, so callers can pre-compute effective permissions once per
+// request instead of calling Search/Allow for every PolicyRule check.
+type RuleResolver struct {
+	designation Designation
+}
+
+// NewRuleResolver returns a RuleResolver backed by d.
+func NewRuleResolver(d Designation) *RuleResolver {
+	return &RuleResolver{designation: d}
+}
+
+// RulesFor returns the PolicyRules applicable to ctx under filter. If
+// the underlying Designation implements RuleSearcher, its structured
+// rules are returned directly; otherwise RulesFor falls back to
+// Search's legacy "permission:resource" strings and adapts each line
+// into a single-verb, single-resource PolicyRule.
+func (r *RuleResolver) RulesFor(ctx Identity, filter string) ([]*PolicyRule, error) {
+	if searcher, ok := r.designation.(RuleSearcher); ok {
+		return searcher.SearchRules(ctx, filter)
+	}
+
+	var buf bytes.Buffer
+	if err := r.designation.Search(ctx, &buf, filter); err != nil {
+		return nil, err
+	}
+	return parseLegacyRules(buf.String()), nil
+}
+
+// parseLegacyRules adapts Search's "permission:resource" lines into
+// PolicyRules, one per line, so callers of RuleResolver see a single
+// rule model regardless of which Designation they're backed by.
+func parseLegacyRules(search string) []*PolicyRule {
+	var rules []*PolicyRule
+	for _, line := range strings.Split(strings.TrimSpace(search), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		permission, resource, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		rules = append(rules, &PolicyRule{
+			Verbs:     []string{permission},
+			Resources: []string{resource},
+		})
+	}
+	return rules
+}
+
+// RuleBasedAuthorizer is an Authorizer backed by a RuleResolver: it
+// resolves ctx's applicable rules once per Authorize call and returns
+// DecisionAllow as soon as one of them allows attrs.
+type RuleBasedAuthorizer struct {
+	resolver *RuleResolver
+}
+
+// NewRuleBasedAuthorizer returns a RuleBasedAuthorizer over resolver.
+func NewRuleBasedAuthorizer(resolver *RuleResolver) *RuleBasedAuthorizer {
+	return &RuleBasedAuthorizer{resolver: resolver}
+}
+
+// Authorize implements Authorizer.
+func (a *RuleBasedAuthorizer) Authorize(ctx Identity, attrs Attributes) (Decision, string, error) {
+	rules, err := a.resolver.RulesFor(ctx, "")
+	if err != nil {
+		return DecisionNoOpinion, "", err
+	}
+	for _, rule := range rules {
+		if attrs.NonResourceURL != "" {
+			if NonResourceURLMatches(rule, attrs.NonResourceURL) {
+				return DecisionAllow, "", nil
+			}
+			continue
+		}
+		if RuleAllows(rule, attrs) {
+			return DecisionAllow, "", nil
+		}
+	}
+	return DecisionNoOpinion, "no PolicyRule matched the request", nil
+}
@@ -0,0 +1,292 @@
+package qra
+
+// DecisionSkip is an alias for DecisionNoOpinion, read by Evaluator
+// implementations as "this evaluator has nothing to say about the
+// request, ask the next one in the Chain."
+const DecisionSkip = DecisionNoOpinion
+
+// PermissionRequest describes a single permission check passed
+// through an Evaluator chain. Not every field is meaningful to every
+// built-in Evaluator: RBACEvaluator and DACEvaluator only look at
+// Attributes, MACEvaluator only looks at Label, ABACEvaluator looks at
+// Expression/Context plus Attributes.Verb and Attributes.Resource, and
+// ZBACEvaluator checks CapabilityToken against Attributes.
+// MACEvaluator, ABACEvaluator and ZBACEvaluator return DecisionSkip
+// when Label, Expression or CapabilityToken respectively is unset.
+type PermissionRequest struct {
+	Attributes Attributes
+
+	// Label is the resource's security classification, compared
+	// against the identity's clearance by MACEvaluator's Lattice.
+	Label string
+
+	// Expression is a caller-supplied boolean expression evaluated by
+	// ABACEvaluator's ExpressionEvaluator, e.g. a CEL program.
+	Expression string
+
+	// Context supplies extra named attributes merged into the
+	// identity+resource attribute set an ABACEvaluator exposes to
+	// Expression.
+	Context map[string]interface{}
+
+	// CapabilityToken is an unforgeable token presented alongside the
+	// request and checked by ZBACEvaluator's CapabilityVerifier.
+	CapabilityToken string
+}
+
+// Evaluator decides whether ctx may perform the request described by
+// req. It returns DecisionSkip when it has no opinion, so Chain can
+// fall through to the next Evaluator.
+type Evaluator interface {
+	Evaluate(ctx Identity, req PermissionRequest) (Decision, error)
+}
+
+// evaluatorChain implements Evaluator by walking its evaluators in
+// order, stopping at the first DecisionAllow/DecisionDeny.
+type evaluatorChain []Evaluator
+
+// Chain combines evaluators into a single Evaluator that walks them
+// in order, returning as soon as one returns DecisionAllow or
+// DecisionDeny, and continuing past any that return DecisionSkip. If
+// every evaluator skips, Chain itself returns DecisionSkip.
+func Chain(evaluators ...Evaluator) Evaluator {
+	return evaluatorChain(evaluators)
+}
+
+// Evaluate implements Evaluator.
+func (c evaluatorChain) Evaluate(ctx Identity, req PermissionRequest) (Decision, error) {
+	for _, e := range c {
+		decision, err := e.Evaluate(ctx, req)
+		if err != nil {
+			return DecisionDeny, err
+		}
+		if decision != DecisionSkip {
+			return decision, nil
+		}
+	}
+	return DecisionSkip, nil
+}
+
+// RoleSource supplies the roles assigned to an identity and the
+// PolicyRules granted to a role, so RBACEvaluator can do its
+// role->permission lookup without depending on a specific storage
+// backend.
+type RoleSource interface {
+	RolesFor(ctx Identity) ([]string, error)
+	RulesForRole(role string) ([]*PolicyRule, error)
+}
+
+// RBACEvaluator grants access when one of ctx's roles, as reported by
+// a RoleSource, has a PolicyRule allowing the request.
+type RBACEvaluator struct {
+	roles RoleSource
+}
+
+// NewRBACEvaluator returns an RBACEvaluator backed by roles.
+func NewRBACEvaluator(roles RoleSource) *RBACEvaluator {
+	return &RBACEvaluator{roles: roles}
+}
+
+// Evaluate implements Evaluator.
+func (e *RBACEvaluator) Evaluate(ctx Identity, req PermissionRequest) (Decision, error) {
+	roleNames, err := e.roles.RolesFor(ctx)
+	if err != nil {
+		return DecisionDeny, err
+	}
+	for _, name := range roleNames {
+		rules, err := e.roles.RulesForRole(name)
+		if err != nil {
+			return DecisionDeny, err
+		}
+		for _, rule := range rules {
+			if RuleAllows(rule, req.Attributes) {
+				return DecisionAllow, nil
+			}
+		}
+	}
+	return DecisionSkip, nil
+}
+
+// DACEvaluator grants access when ctx itself holds a PolicyRule
+// allowing the request, via the existing Designation Allow/Revoke
+// owner-grant model.
+type DACEvaluator struct {
+	resolver *RuleResolver
+}
+
+// NewDACEvaluator returns a DACEvaluator resolving rules from d.
+func NewDACEvaluator(d Designation) *DACEvaluator {
+	return &DACEvaluator{resolver: NewRuleResolver(d)}
+}
+
+// Evaluate implements Evaluator.
+func (e *DACEvaluator) Evaluate(ctx Identity, req PermissionRequest) (Decision, error) {
+	rules, err := e.resolver.RulesFor(ctx, "")
+	if err != nil {
+		return DecisionDeny, err
+	}
+	for _, rule := range rules {
+		if RuleAllows(rule, req.Attributes) {
+			return DecisionAllow, nil
+		}
+	}
+	return DecisionSkip, nil
+}
+
+// Lattice orders security labels from least to most sensitive for
+// MACEvaluator: an identity whose clearance Level is at least a
+// resource label's Level may access it.
+type Lattice interface {
+	Level(label string) int
+}
+
+// ClearanceSource returns the security clearance label assigned to an
+// identity, for comparison against a PermissionRequest's Label.
+type ClearanceSource interface {
+	ClearanceFor(ctx Identity) (string, error)
+}
+
+// MACEvaluator grants access when ctx's clearance, as ordered by a
+// caller-supplied Lattice, dominates a PermissionRequest's Label. It
+// skips requests that don't set Label.
+type MACEvaluator struct {
+	lattice   Lattice
+	clearance ClearanceSource
+}
+
+// NewMACEvaluator returns a MACEvaluator comparing clearance against
+// lattice.
+func NewMACEvaluator(lattice Lattice, clearance ClearanceSource) *MACEvaluator {
+	return &MACEvaluator{lattice: lattice, clearance: clearance}
+}
+
+// Evaluate implements Evaluator.
+func (e *MACEvaluator) Evaluate(ctx Identity, req PermissionRequest) (Decision, error) {
+	if req.Label == "" {
+		return DecisionSkip, nil
+	}
+	clearance, err := e.clearance.ClearanceFor(ctx)
+	if err != nil {
+		return DecisionDeny, err
+	}
+	if e.lattice.Level(clearance) >= e.lattice.Level(req.Label) {
+		return DecisionAllow, nil
+	}
+	return DecisionDeny, nil
+}
+
+// ExpressionEvaluator evaluates a boolean expression against a set of
+// named attributes, so ABACEvaluator can plug in CEL, a small
+// expression language, or anything else without qra depending on one
+// directly.
+type ExpressionEvaluator interface {
+	EvalBool(expression string, attributes map[string]interface{}) (bool, error)
+}
+
+// ABACEvaluator grants access when a PermissionRequest's Expression
+// evaluates true against the identity, its Attributes and its
+// Context, as judged by a caller-supplied ExpressionEvaluator. It
+// skips requests that don't set Expression.
+type ABACEvaluator struct {
+	expr ExpressionEvaluator
+}
+
+// NewABACEvaluator returns an ABACEvaluator backed by expr.
+func NewABACEvaluator(expr ExpressionEvaluator) *ABACEvaluator {
+	return &ABACEvaluator{expr: expr}
+}
+
+// Evaluate implements Evaluator.
+func (e *ABACEvaluator) Evaluate(ctx Identity, req PermissionRequest) (Decision, error) {
+	if req.Expression == "" {
+		return DecisionSkip, nil
+	}
+
+	attributes := map[string]interface{}{
+		"identity": ctx.Me(),
+		"verb":     req.Attributes.Verb,
+		"resource": req.Attributes.Resource,
+	}
+	for k, v := range req.Context {
+		attributes[k] = v
+	}
+
+	allowed, err := e.expr.EvalBool(req.Expression, attributes)
+	if err != nil {
+		return DecisionDeny, err
+	}
+	if allowed {
+		return DecisionAllow, nil
+	}
+	return DecisionDeny, nil
+}
+
+// CapabilityVerifier verifies an unforgeable capability token
+// presented alongside a request, for ZBACEvaluator.
+type CapabilityVerifier interface {
+	Verify(token string, attrs Attributes) (bool, error)
+}
+
+// ZBACEvaluator grants access when a PermissionRequest's
+// CapabilityToken verifies against its Attributes, as judged by a
+// caller-supplied CapabilityVerifier. It skips requests that don't
+// present a CapabilityToken.
+type ZBACEvaluator struct {
+	verifier CapabilityVerifier
+}
+
+// NewZBACEvaluator returns a ZBACEvaluator backed by verifier.
+func NewZBACEvaluator(verifier CapabilityVerifier) *ZBACEvaluator {
+	return &ZBACEvaluator{verifier: verifier}
+}
+
+// Evaluate implements Evaluator.
+func (e *ZBACEvaluator) Evaluate(ctx Identity, req PermissionRequest) (Decision, error) {
+	if req.CapabilityToken == "" {
+		return DecisionSkip, nil
+	}
+	allowed, err := e.verifier.Verify(req.CapabilityToken, req.Attributes)
+	if err != nil {
+		return DecisionDeny, err
+	}
+	if allowed {
+		return DecisionAllow, nil
+	}
+	return DecisionDeny, nil
+}
+
+// namedEvaluator pairs an Evaluator with the name it was registered
+// under, so QRA.evaluators preserves registration order (unlike a
+// map) for Chain to walk.
+type namedEvaluator struct {
+	name      string
+	evaluator Evaluator
+}
+
+// RegisterEvaluator adds e to q under name; it will be consulted, in
+// registration order, by q.Authorize.
+func (q *QRA) RegisterEvaluator(name string, e Evaluator) {
+	q.evaluators = append(q.evaluators, namedEvaluator{name: name, evaluator: e})
+}
+
+// Authorize walks q's registered evaluators in registration order via
+// Chain, so applications can mix models (e.g. RBAC + ABAC overrides)
+// without each Designation implementation reimplementing the
+// composition logic.
+func (q *QRA) Authorize(ctx Identity, req PermissionRequest) (Decision, error) {
+	chain := make([]Evaluator, len(q.evaluators))
+	for i, ne := range q.evaluators {
+		chain[i] = ne.evaluator
+	}
+	return Chain(chain...).Evaluate(ctx, req)
+}
+
+// RegisterEvaluator wrapper for DefaultManager.RegisterEvaluator.
+func RegisterEvaluator(name string, e Evaluator) {
+	DefaultManager.RegisterEvaluator(name, e)
+}
+
+// Authorize wrapper for DefaultManager.Authorize.
+func Authorize(ctx Identity, req PermissionRequest) (Decision, error) {
+	return DefaultManager.Authorize(ctx, req)
+}
@@ -0,0 +1,58 @@
+package qra
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeDACDesignation is a minimal Designation backed by a static set of
+// "permission:resource" grants, exercising RuleResolver's legacy
+// Search-parsing fallback (it deliberately does not implement
+// RuleSearcher).
+type fakeDACDesignation struct {
+	grants []string
+}
+
+func (d *fakeDACDesignation) Search(ctx Identity, w io.Writer, filter string) error {
+	for _, g := range d.grants {
+		if _, err := fmt.Fprintln(w, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *fakeDACDesignation) Allow(ctx Identity, permission, resource, dst string, expiresAt time.Time) error {
+	return nil
+}
+
+func (d *fakeDACDesignation) Revoke(ctx Identity, permission, dst string) error {
+	return nil
+}
+
+type fakeDACIdentity struct{ name string }
+
+func (f fakeDACIdentity) Me() string                    { return f.name }
+func (f fakeDACIdentity) Session(dst interface{}) error { return nil }
+
+// TestDACEvaluatorAllowsOwnedGrant guards against RuleAllows rejecting
+// every rule RuleResolver produces because PolicyRule.APIGroups comes
+// back empty (neither parseLegacyRules nor SearchRules populate it);
+// APIGroupMatches must treat that as "any group" for DAC to ever
+// return DecisionAllow.
+func TestDACEvaluatorAllowsOwnedGrant(t *testing.T) {
+	designation := &fakeDACDesignation{grants: []string{"read:catalog"}}
+	evaluator := NewDACEvaluator(designation)
+
+	decision, err := evaluator.Evaluate(fakeDACIdentity{name: "alice"}, PermissionRequest{
+		Attributes: Attributes{Verb: "read", Resource: "catalog"},
+	})
+	if err != nil {
+		t.Fatalf("Evaluate : err [%s]", err)
+	}
+	if decision != DecisionAllow {
+		t.Errorf("Evaluate : want DecisionAllow for owned grant, got %v", decision)
+	}
+}
@@ -0,0 +1,103 @@
+package qra
+
+import "time"
+
+// IdentityKind classifies an Identity as a human user or a non-human
+// identity such as a CI/automation robot or another service.
+type IdentityKind int
+
+const (
+	// KindUser is a human-operated identity. It is the default for
+	// any Identity that does not implement Kinded.
+	KindUser IdentityKind = iota
+
+	// KindRobot is a scoped, non-interactive identity minted by
+	// RobotDesignation.AllowRobot, typically used by CI/automation.
+	KindRobot
+
+	// KindService is a non-human identity representing another
+	// service, as opposed to a single-purpose robot credential.
+	KindService
+)
+
+// String returns the lower-case name of k.
+func (k IdentityKind) String() string {
+	switch k {
+	case KindRobot:
+		return "robot"
+	case KindService:
+		return "service"
+	default:
+		return "user"
+	}
+}
+
+// Kinded is an optional extension of Identity, implemented by
+// Identities that know their own IdentityKind. Use KindOf rather than
+// a type assertion directly, so callers don't need special-casing for
+// Identities that predate Kinded.
+type Kinded interface {
+	Kind() IdentityKind
+}
+
+// KindOf returns ctx.Kind() if ctx implements Kinded, and KindUser
+// otherwise. This lets Kinded be adopted incrementally: an Identity
+// implementation that doesn't implement it keeps compiling and is
+// simply treated as a regular user.
+func KindOf(ctx Identity) IdentityKind {
+	if k, ok := ctx.(Kinded); ok {
+		return k.Kind()
+	}
+	return KindUser
+}
+
+// SystemResource names a resource that is global to a qra deployment
+// rather than scoped to a particular owner, for use as the Resource
+// argument to Allow/Revoke or as Attributes.Resource in an Authorizer
+// check.
+type SystemResource string
+
+// Predefined SystemResource values for the administrative operations
+// qra deployments commonly need to gate.
+const (
+	ResourceCatalog           SystemResource = "catalog"
+	ResourceUser              SystemResource = "user"
+	ResourceUserGroup         SystemResource = "user-group"
+	ResourceReplication       SystemResource = "replication"
+	ResourceGarbageCollection SystemResource = "garbage-collection"
+	ResourceAuditLog          SystemResource = "audit-log"
+
+	// ResourceSelf refers to the acting identity's own record (e.g.
+	// updating one's own password), distinct from ResourceUser which
+	// covers managing other users.
+	ResourceSelf SystemResource = "self"
+)
+
+// PermissionGrant is a single permission/resource/expiry tuple used
+// to describe the scope a robot identity is minted with.
+type PermissionGrant struct {
+	Permission string
+	Resource   string
+	ExpiresAt  time.Time
+}
+
+// RobotSpec describes a robot/service identity to be created by
+// RobotDesignation.AllowRobot. Permissions must be a subset of the
+// creating identity's own permissions; implementations enforce this
+// with an Authorizer before minting a credential.
+type RobotSpec struct {
+	Name        string
+	Permissions []PermissionGrant
+}
+
+// RobotDesignation is an optional extension of Designation,
+// implemented by Designations that can mint scoped, non-human
+// identities. AllowRobot returns a bearer credential the robot
+// presents as its Identity.
+type RobotDesignation interface {
+	// AllowRobot grants ctx's own permissions, intersected with
+	// spec.Permissions, to a new robot identity, and returns a bearer
+	// token for it. AllowRobot must fail if spec.Permissions requests
+	// anything ctx does not itself hold.
+	AllowRobot(ctx Identity, spec RobotSpec) (token string, err error)
+}
@@ -0,0 +1,26 @@
+package jwtauth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims wraps jwt.MapClaims so jwtauth can attach helpers without
+// exporting a competing claims type.
+type Claims struct {
+	jwt.MapClaims
+}
+
+// newJTI returns a fresh random token identifier suitable for the
+// "jti" claim and for keying the RevocationStore.
+func newJTI() string {
+	buf := make([]byte, 16)
+	// crypto/rand.Read never returns a short read without an error,
+	// and a failing entropy source is fatal to the process anyway.
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,106 @@
+package jwtauth
+
+import (
+	"encoding/json"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Identity adapts a bearer token string into a qra.Identity. Callers
+// typically construct one per incoming request from the Authorization
+// header and pass it to qra.Authenticate, qra.DefaultManager calls and
+// (*JWTAuth).Close.
+type Identity struct {
+	name  string
+	token string
+	auth  *JWTAuth
+}
+
+// NewIdentity returns an Identity for name carrying token, verified
+// lazily against auth's KeyRotator and RevocationStore each time
+// Session is called.
+func NewIdentity(name, token string, auth *JWTAuth) *Identity {
+	return &Identity{name: name, token: token, auth: auth}
+}
+
+// Me returns the name i was constructed with. It is not read from, or
+// checked against, the token's "sub" claim.
+func (i *Identity) Me() string {
+	return i.name
+}
+
+// Session parses and verifies i's token and writes its claims into
+// dst. dst may be:
+//
+//   - *string: the raw compact token is written back unchanged.
+//   - *jwt.MapClaims: the parsed claim set is written directly.
+//   - a pointer to any other struct: the claim set is round-tripped
+//     through encoding/json into dst, so fields are matched by their
+//     `json` tags.
+//
+// Session returns ErrSessionRevoked if the token's jti has been
+// pushed onto the RevocationStore by a prior Close.
+func (i *Identity) Session(dst interface{}) error {
+	claims, err := i.verify()
+	if err != nil {
+		return err
+	}
+
+	if out, ok := dst.(*string); ok {
+		*out = i.token
+		return nil
+	}
+
+	if out, ok := dst.(*jwt.MapClaims); ok {
+		*out = claims
+		return nil
+	}
+
+	raw, err := json.Marshal(claims)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return ErrDestinationUnsupported
+	}
+	return nil
+}
+
+// verify parses i.token, trying each of the KeyRotator's acceptable
+// verification keys in turn so a token signed under a since-rotated
+// key still verifies until it expires.
+func (i *Identity) verify() (jwt.MapClaims, error) {
+	acceptable := i.auth.keys.VerificationKeys()
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{string(i.auth.cfg.Algorithm)}),
+		jwt.WithLeeway(i.auth.cfg.Leeway),
+	)
+
+	var lastErr error
+	for _, key := range acceptable {
+		claims := jwt.MapClaims{}
+		_, err := parser.ParseWithClaims(i.token, claims, func(*jwt.Token) (interface{}, error) {
+			return key, nil
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		jti, _ := claims["jti"].(string)
+		if jti != "" {
+			revoked, err := i.auth.revocation.IsRevoked(jti)
+			if err != nil {
+				return nil, err
+			}
+			if revoked {
+				return nil, ErrSessionRevoked
+			}
+		}
+		return claims, nil
+	}
+	if lastErr == nil {
+		lastErr = jwt.ErrTokenSignatureInvalid
+	}
+	return nil, lastErr
+}
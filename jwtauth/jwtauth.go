@@ -0,0 +1,211 @@
+// Package jwtauth implements qra.Authentication on top of self-contained,
+// signed JWT bearer tokens instead of server-side session rows, so that
+// services can validate qra.Identity sessions without a shared database.
+//
+// MIT License
+//
+// Copyright (c) 2016 Angel Del Castillo
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package jwtauth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/AthulMuralidhar/qra"
+)
+
+// Algorithm identifies the signing algorithm a Config should use.
+type Algorithm string
+
+const (
+	// HS256 signs and verifies tokens with a single shared secret.
+	HS256 Algorithm = "HS256"
+
+	// RS256 signs tokens with an RSA private key and verifies them
+	// with the matching public key(s).
+	RS256 Algorithm = "RS256"
+
+	// ES256 signs tokens with an ECDSA private key and verifies them
+	// with the matching public key(s).
+	ES256 Algorithm = "ES256"
+)
+
+var (
+	// ErrCredentialStoreNil returned when a JWTAuth is constructed
+	// without a CredentialStore.
+	ErrCredentialStoreNil = errors.New("jwtauth: credential store is nil")
+
+	// ErrKeyRotatorNil returned when a JWTAuth is constructed without
+	// a KeyRotator.
+	ErrKeyRotatorNil = errors.New("jwtauth: key rotator is nil")
+
+	// ErrInvalidCredentials returned when Authenticate is called with
+	// a password that does not match the stored credential.
+	ErrInvalidCredentials = errors.New("jwtauth: invalid credentials")
+
+	// ErrSessionRevoked returned when the token presented to Session
+	// (and so to Close) has a jti present in the revocation set.
+	ErrSessionRevoked = errors.New("jwtauth: session has been revoked")
+
+	// ErrDestinationUnsupported returned when Session is called with a
+	// dst that is neither *string, *jwt.MapClaims nor a struct pointer.
+	ErrDestinationUnsupported = errors.New("jwtauth: unsupported session destination")
+)
+
+// CredentialStore validates a plaintext password for an identity name.
+// Implementations typically wrap an existing user table or directory
+// service; jwtauth never stores passwords itself.
+type CredentialStore interface {
+	// Validate returns nil if password is correct for name, and
+	// ErrInvalidCredentials (or a wrapped form of it) otherwise.
+	Validate(name, password string) error
+
+	// Permissions returns a set of permissions to pre-fetch and embed
+	// in the minted token's claims, keyed by permission name. It may
+	// return a nil map if the caller resolves permissions out of band.
+	Permissions(name string) (map[string]string, error)
+}
+
+// Config configures a JWTAuth instance.
+type Config struct {
+	// Algorithm selects HS256, RS256 or ES256. Defaults to HS256.
+	Algorithm Algorithm
+
+	// Issuer is written to the "iss" claim of minted tokens.
+	Issuer string
+
+	// TTL is how long a minted token remains valid for.
+	TTL time.Duration
+
+	// Leeway is the clock-skew tolerance applied when verifying the
+	// "exp" and "nbf" claims.
+	Leeway time.Duration
+}
+
+// JWTAuth implements qra.Authentication by minting and verifying signed
+// JWT bearer tokens. Build one with New.
+type JWTAuth struct {
+	cfg        Config
+	credential CredentialStore
+	keys       KeyRotator
+	revocation RevocationStore
+}
+
+// New returns a JWTAuth ready to be registered with
+// qra.RegisterAuthentication. credential validates passwords, keys
+// supplies signing/verification keys and rotation. If revocation is
+// nil, an in-memory LRU RevocationStore is used.
+func New(cfg Config, credential CredentialStore, keys KeyRotator, revocation RevocationStore) (*JWTAuth, error) {
+	if credential == nil {
+		return nil, ErrCredentialStoreNil
+	}
+	if keys == nil {
+		return nil, ErrKeyRotatorNil
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = HS256
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	if revocation == nil {
+		revocation = NewMemoryRevocationStore(defaultRevocationCapacity)
+	}
+	return &JWTAuth{
+		cfg:        cfg,
+		credential: credential,
+		keys:       keys,
+		revocation: revocation,
+	}, nil
+}
+
+// Authenticate validates password against the configured
+// CredentialStore and, on success, mints a signed token for
+// ctx.Me() and writes the compact token string into dst, which must
+// be a *string.
+func (a *JWTAuth) Authenticate(ctx qra.Identity, password string, dst interface{}) error {
+	name := ctx.Me()
+	if err := a.credential.Validate(name, password); err != nil {
+		return err
+	}
+
+	permissions, err := a.credential.Permissions(name)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	claims := Claims{
+		MapClaims: jwt.MapClaims{
+			"sub": name,
+			"iat": now.Unix(),
+			"exp": now.Add(a.cfg.TTL).Unix(),
+			"jti": newJTI(),
+		},
+	}
+	if a.cfg.Issuer != "" {
+		claims.MapClaims["iss"] = a.cfg.Issuer
+	}
+	if len(permissions) > 0 {
+		claims.MapClaims["permissions"] = permissions
+	}
+
+	token := jwt.NewWithClaims(signingMethod(a.cfg.Algorithm), claims.MapClaims)
+	signed, err := token.SignedString(a.keys.SigningKey())
+	if err != nil {
+		return err
+	}
+
+	out, ok := dst.(*string)
+	if !ok {
+		return ErrDestinationUnsupported
+	}
+	*out = signed
+	return nil
+}
+
+// Close revokes the session carried by ctx by pushing its jti onto the
+// configured RevocationStore, so the verify path in Session rejects it
+// even though the token itself remains structurally valid until exp.
+func (a *JWTAuth) Close(ctx qra.Identity) error {
+	var claims jwt.MapClaims
+	if err := ctx.Session(&claims); err != nil {
+		return err
+	}
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return nil
+	}
+	return a.revocation.Revoke(jti)
+}
+
+func signingMethod(alg Algorithm) jwt.SigningMethod {
+	switch alg {
+	case RS256:
+		return jwt.SigningMethodRS256
+	case ES256:
+		return jwt.SigningMethodES256
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
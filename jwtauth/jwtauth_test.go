@@ -0,0 +1,78 @@
+package jwtauth
+
+import (
+	"testing"
+)
+
+type testIdentity struct {
+	name    string
+	session *Identity
+}
+
+func (t *testIdentity) Me() string { return t.name }
+
+func (t *testIdentity) Session(dst interface{}) error {
+	return t.session.Session(dst)
+}
+
+type staticCredentials struct{}
+
+func (staticCredentials) Validate(name, password string) error {
+	if password != "correct-horse-battery-staple" {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+func (staticCredentials) Permissions(name string) (map[string]string, error) {
+	return map[string]string{"read": "*"}, nil
+}
+
+func TestAuthenticateAndClose(t *testing.T) {
+	keys := NewStaticKeyRotator([]byte("test-secret"))
+	auth, err := New(Config{}, staticCredentials{}, keys, nil)
+	if err != nil {
+		t.Fatalf("New : err [%s]", err)
+	}
+
+	var token string
+	identity := &testIdentity{name: "alice"}
+	if err := auth.Authenticate(identity, "correct-horse-battery-staple", &token); err != nil {
+		t.Fatalf("Authenticate : err [%s]", err)
+	}
+	if token == "" {
+		t.Fatal("Authenticate : empty token")
+	}
+
+	identity.session = NewIdentity("alice", token, auth)
+
+	var claims map[string]interface{}
+	if err := identity.session.Session(&claims); err != nil {
+		t.Fatalf("Session : err [%s]", err)
+	}
+	if claims["sub"] != "alice" {
+		t.Errorf("Session : sub [%v], want [alice]", claims["sub"])
+	}
+
+	if err := auth.Close(identity); err != nil {
+		t.Fatalf("Close : err [%s]", err)
+	}
+
+	if _, err := identity.session.verify(); err != ErrSessionRevoked {
+		t.Errorf("verify after Close : err [%v], want [%s]", err, ErrSessionRevoked)
+	}
+}
+
+func TestAuthenticateInvalidPassword(t *testing.T) {
+	keys := NewStaticKeyRotator([]byte("test-secret"))
+	auth, err := New(Config{}, staticCredentials{}, keys, nil)
+	if err != nil {
+		t.Fatalf("New : err [%s]", err)
+	}
+
+	var token string
+	identity := &testIdentity{name: "alice"}
+	if err := auth.Authenticate(identity, "wrong-password", &token); err != ErrInvalidCredentials {
+		t.Errorf("Authenticate : err [%v], want [%s]", err, ErrInvalidCredentials)
+	}
+}
@@ -0,0 +1,48 @@
+package jwtauth
+
+// KeyRotator supplies the signing key used to mint new tokens and the
+// set of keys that should still be accepted when verifying existing
+// ones, so keys can be rotated without invalidating sessions minted
+// under the previous key.
+type KeyRotator interface {
+	// SigningKey returns the key new tokens are signed with. Its
+	// concrete type must match the configured Algorithm: a []byte
+	// secret for HS256, or an *rsa.PrivateKey / *ecdsa.PrivateKey for
+	// RS256 / ES256 respectively.
+	SigningKey() interface{}
+
+	// VerificationKeys returns every key that should still verify a
+	// presented token, ordered most-recent first. It must include the
+	// current SigningKey (or its public half, for RS256/ES256).
+	VerificationKeys() []interface{}
+}
+
+// StaticKeyRotator is a KeyRotator with a fixed signing key and a
+// fixed list of acceptable verification keys. It performs no rotation
+// itself; callers that need rotation should swap keys atomically
+// (e.g. behind a mutex or atomic.Value) in their own KeyRotator and
+// use StaticKeyRotator only for tests or single-key deployments.
+type StaticKeyRotator struct {
+	signing      interface{}
+	verification []interface{}
+}
+
+// NewStaticKeyRotator returns a KeyRotator that always signs with
+// signing and verifies against verification. If verification is empty,
+// signing is also used to verify.
+func NewStaticKeyRotator(signing interface{}, verification ...interface{}) *StaticKeyRotator {
+	if len(verification) == 0 {
+		verification = []interface{}{signing}
+	}
+	return &StaticKeyRotator{signing: signing, verification: verification}
+}
+
+// SigningKey implements KeyRotator.
+func (s *StaticKeyRotator) SigningKey() interface{} {
+	return s.signing
+}
+
+// VerificationKeys implements KeyRotator.
+func (s *StaticKeyRotator) VerificationKeys() []interface{} {
+	return s.verification
+}
@@ -0,0 +1,45 @@
+package jwtauth
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRevocationStore is a RevocationStore backed by Redis, one key
+// per revoked jti, suitable for sharing revocations across multiple
+// service instances. Each key is stored with ttl as its expiry, since a jti only
+// needs to be remembered for as long as its token would otherwise
+// remain valid.
+type RedisRevocationStore struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisRevocationStore returns a RevocationStore that stores
+// revoked jti values as keys under prefix in client, each expiring
+// after ttl. ttl should be set to at least the JWTAuth Config's TTL.
+func NewRedisRevocationStore(client *redis.Client, prefix string, ttl time.Duration) *RedisRevocationStore {
+	if prefix == "" {
+		prefix = "qra:jwtauth:revoked:"
+	}
+	return &RedisRevocationStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+// Revoke implements RevocationStore.
+func (r *RedisRevocationStore) Revoke(jti string) error {
+	ctx := context.Background()
+	return r.client.Set(ctx, r.prefix+jti, true, r.ttl).Err()
+}
+
+// IsRevoked implements RevocationStore.
+func (r *RedisRevocationStore) IsRevoked(jti string) (bool, error) {
+	ctx := context.Background()
+	n, err := r.client.Exists(ctx, r.prefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
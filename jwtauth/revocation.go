@@ -0,0 +1,77 @@
+package jwtauth
+
+import (
+	"container/list"
+	"sync"
+)
+
+// defaultRevocationCapacity bounds the in-memory LRU used when New is
+// called without an explicit RevocationStore.
+const defaultRevocationCapacity = 10000
+
+// RevocationStore records jti values of closed sessions so the verify
+// path can reject an otherwise-valid token whose session was ended
+// early. Implementations must be safe for concurrent use.
+type RevocationStore interface {
+	// Revoke marks jti as revoked.
+	Revoke(jti string) error
+
+	// IsRevoked reports whether jti has been revoked.
+	IsRevoked(jti string) (bool, error)
+}
+
+// MemoryRevocationStore is the default RevocationStore: an in-process
+// LRU set bounded by capacity, so long-running processes don't
+// accumulate jti values forever. It is only suitable for a single
+// process; multi-instance deployments should use a shared store such
+// as the Redis or SQLite adapters in this package.
+type MemoryRevocationStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	index    map[string]*list.Element
+}
+
+// NewMemoryRevocationStore returns a MemoryRevocationStore holding at
+// most capacity revoked jti values, evicting the least-recently-used
+// (revoked or looked up) once that limit is reached.
+func NewMemoryRevocationStore(capacity int) *MemoryRevocationStore {
+	if capacity <= 0 {
+		capacity = defaultRevocationCapacity
+	}
+	return &MemoryRevocationStore{
+		capacity: capacity,
+		order:    list.New(),
+		index:    make(map[string]*list.Element),
+	}
+}
+
+// Revoke implements RevocationStore.
+func (m *MemoryRevocationStore) Revoke(jti string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.index[jti]; ok {
+		m.order.MoveToBack(el)
+		return nil
+	}
+	m.index[jti] = m.order.PushBack(jti)
+	if m.order.Len() > m.capacity {
+		oldest := m.order.Front()
+		m.order.Remove(oldest)
+		delete(m.index, oldest.Value.(string))
+	}
+	return nil
+}
+
+// IsRevoked implements RevocationStore.
+func (m *MemoryRevocationStore) IsRevoked(jti string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.index[jti]
+	if ok {
+		m.order.MoveToBack(el)
+	}
+	return ok, nil
+}
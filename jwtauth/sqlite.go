@@ -0,0 +1,49 @@
+package jwtauth
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SQLiteRevocationStore is a RevocationStore backed by a table in an
+// existing SQLite database, so a qra deployment that already ships
+// pgmanager's SQLite backend can share its connection rather than
+// running a separate Redis instance just for revocations.
+type SQLiteRevocationStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteRevocationStore returns a RevocationStore backed by db. It
+// creates its revoked_tokens table if it does not already exist.
+func NewSQLiteRevocationStore(db *sql.DB) (*SQLiteRevocationStore, error) {
+	const schema = `CREATE TABLE IF NOT EXISTS revoked_tokens (
+		jti TEXT PRIMARY KEY,
+		revoked_at INTEGER NOT NULL
+	)`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	return &SQLiteRevocationStore{db: db}, nil
+}
+
+// Revoke implements RevocationStore.
+func (s *SQLiteRevocationStore) Revoke(jti string) error {
+	_, err := s.db.Exec(
+		`INSERT OR IGNORE INTO revoked_tokens (jti, revoked_at) VALUES (?, ?)`,
+		jti, time.Now().Unix(),
+	)
+	return err
+}
+
+// IsRevoked implements RevocationStore.
+func (s *SQLiteRevocationStore) IsRevoked(jti string) (bool, error) {
+	var found string
+	err := s.db.QueryRow(`SELECT jti FROM revoked_tokens WHERE jti = ?`, jti).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
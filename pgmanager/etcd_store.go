@@ -0,0 +1,313 @@
+package pgmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+func init() {
+	registerDriver("etcd", newEtcdStore)
+}
+
+const (
+	etcdUserPrefix  = "/qra/users/"
+	etcdRolePrefix  = "/qra/roles/"
+	etcdGrantPrefix = "/qra/grants/"
+	etcdRobotPrefix = "/qra/robots/"
+)
+
+// etcdStore is a horizontally-scalable store backend on top of an
+// etcd v3 cluster. Keys are namespaced under /qra/... and grants use a
+// lease to implement Grant.ExpiresAt, so an expired grant disappears
+// from etcd on its own instead of needing a reaper.
+type etcdStore struct {
+	client *clientv3.Client
+	debug  bool
+}
+
+func newEtcdStore(dsn string, opts *options) (store, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   strings.Split(dsn, ","),
+		DialTimeout: opts.etcdDialTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &etcdStore{client: client, debug: opts.debug}, nil
+}
+
+func userKey(id string) string { return etcdUserPrefix + id }
+func roleKey(id string) string { return etcdRolePrefix + id }
+
+func grantKey(owner, dst, permission, resource string) string {
+	return fmt.Sprintf("%s%s/%s/%s/%s", etcdGrantPrefix, owner, dst, permission, resource)
+}
+
+func (s *etcdStore) CreateUser(ctx context.Context, u User) error {
+	raw, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Put(ctx, userKey(u.ID), string(raw))
+	return err
+}
+
+func (s *etcdStore) GetUser(ctx context.Context, id string) (User, error) {
+	resp, err := s.client.Get(ctx, userKey(id))
+	if err != nil {
+		return User{}, err
+	}
+	if len(resp.Kvs) == 0 {
+		return User{}, ErrNotFound
+	}
+	var u User
+	if err := json.Unmarshal(resp.Kvs[0].Value, &u); err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+func (s *etcdStore) DeleteUser(ctx context.Context, id string) error {
+	_, err := s.client.Delete(ctx, userKey(id))
+	return err
+}
+
+func (s *etcdStore) CreateRole(ctx context.Context, r Role) error {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Put(ctx, roleKey(r.ID), string(raw))
+	return err
+}
+
+func (s *etcdStore) GetRole(ctx context.Context, id string) (Role, error) {
+	resp, err := s.client.Get(ctx, roleKey(id))
+	if err != nil {
+		return Role{}, err
+	}
+	if len(resp.Kvs) == 0 {
+		return Role{}, ErrNotFound
+	}
+	var r Role
+	if err := json.Unmarshal(resp.Kvs[0].Value, &r); err != nil {
+		return Role{}, err
+	}
+	return r, nil
+}
+
+func (s *etcdStore) DeleteRole(ctx context.Context, id string) error {
+	_, err := s.client.Delete(ctx, roleKey(id))
+	return err
+}
+
+// etcd has no native concept of a "session" row distinct from a
+// user-scoped lease; sessions are stored the same way as users and
+// roles, keyed by session ID, so Authenticate/Close round-trip
+// through the same Get/Put/Delete primitives.
+func (s *etcdStore) sessionKey(id string) string { return "/qra/sessions/" + id }
+
+func (s *etcdStore) CreateSession(ctx context.Context, sess Session) error {
+	raw, err := json.Marshal(sess)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Put(ctx, s.sessionKey(sess.ID), string(raw))
+	return err
+}
+
+func (s *etcdStore) GetSession(ctx context.Context, id string) (Session, error) {
+	resp, err := s.client.Get(ctx, s.sessionKey(id))
+	if err != nil {
+		return Session{}, err
+	}
+	if len(resp.Kvs) == 0 {
+		return Session{}, ErrNotFound
+	}
+	var sess Session
+	if err := json.Unmarshal(resp.Kvs[0].Value, &sess); err != nil {
+		return Session{}, err
+	}
+	return sess, nil
+}
+
+func (s *etcdStore) DeleteSession(ctx context.Context, id string) error {
+	_, err := s.client.Delete(ctx, s.sessionKey(id))
+	return err
+}
+
+// CreateGrant writes g in a single clientv3.Txn. When g expires, the
+// lease is created first by a separate Grant call and attached to the
+// key by that same put, so the grant is never visible without its
+// expiry; a failed put leaves the lease unused.
+func (s *etcdStore) CreateGrant(ctx context.Context, g Grant) error {
+	raw, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+
+	putOp := clientv3.OpPut(grantKey(g.Owner, g.Dst, g.Permission, g.Resource), string(raw))
+	if !g.ExpiresAt.IsZero() {
+		ttl := int64(time.Until(g.ExpiresAt).Seconds())
+		if ttl < 1 {
+			ttl = 1
+		}
+		lease, err := s.client.Grant(ctx, ttl)
+		if err != nil {
+			return err
+		}
+		putOp = clientv3.OpPut(grantKey(g.Owner, g.Dst, g.Permission, g.Resource), string(raw), clientv3.WithLease(lease.ID))
+	}
+
+	_, err = s.client.Txn(ctx).Then(putOp).Commit()
+	return err
+}
+
+func (s *etcdStore) GetGrants(ctx context.Context, grantee string) ([]Grant, error) {
+	resp, err := s.client.Get(ctx, etcdGrantPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	var out []Grant
+	for _, kv := range resp.Kvs {
+		var g Grant
+		if err := json.Unmarshal(kv.Value, &g); err != nil {
+			return nil, err
+		}
+		if g.Dst == grantee {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func (s *etcdStore) DeleteGrant(ctx context.Context, owner, dst, permission string) error {
+	resp, err := s.client.Get(ctx, fmt.Sprintf("%s%s/%s/%s/", etcdGrantPrefix, owner, dst, permission), clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+
+	txn := s.client.Txn(ctx)
+	ops := make([]clientv3.Op, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		ops = append(ops, clientv3.OpDelete(string(kv.Key)))
+	}
+	_, err = txn.Then(ops...).Commit()
+	return err
+}
+
+func robotKey(id string) string { return etcdRobotPrefix + id }
+
+func (s *etcdStore) CreateRobot(ctx context.Context, r Robot) error {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Put(ctx, robotKey(r.ID), string(raw))
+	return err
+}
+
+// GetRobotByToken scans the robots prefix since etcd keys robots by
+// ID, not by token, so its cost grows with the number of robots
+// stored, disabled ones included.
+func (s *etcdStore) GetRobotByToken(ctx context.Context, token string) (Robot, error) {
+	resp, err := s.client.Get(ctx, etcdRobotPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return Robot{}, err
+	}
+	for _, kv := range resp.Kvs {
+		var r Robot
+		if err := json.Unmarshal(kv.Value, &r); err != nil {
+			return Robot{}, err
+		}
+		if r.Token == token {
+			return r, nil
+		}
+	}
+	return Robot{}, ErrNotFound
+}
+
+func (s *etcdStore) GetRobotsByOwner(ctx context.Context, owner string) ([]Robot, error) {
+	resp, err := s.client.Get(ctx, etcdRobotPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	var out []Robot
+	for _, kv := range resp.Kvs {
+		var r Robot
+		if err := json.Unmarshal(kv.Value, &r); err != nil {
+			return nil, err
+		}
+		if r.OwnerID == owner {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (s *etcdStore) DisableRobot(ctx context.Context, id string) error {
+	r, err := s.getRobotByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	r.IsDisabled = true
+	return s.CreateRobot(ctx, r)
+}
+
+func (s *etcdStore) getRobotByID(ctx context.Context, id string) (Robot, error) {
+	resp, err := s.client.Get(ctx, robotKey(id))
+	if err != nil {
+		return Robot{}, err
+	}
+	if len(resp.Kvs) == 0 {
+		return Robot{}, ErrNotFound
+	}
+	var r Robot
+	if err := json.Unmarshal(resp.Kvs[0].Value, &r); err != nil {
+		return Robot{}, err
+	}
+	return r, nil
+}
+
+// WithTx implements store. etcd has no long-lived transaction handle
+// comparable to database/sql's; every CreateGrant/DeleteGrant call is
+// already its own atomic clientv3.Txn, so WithTx just runs fn against
+// the same store.
+func (s *etcdStore) WithTx(ctx context.Context, fn func(tx store) error) error {
+	return fn(s)
+}
+
+func (s *etcdStore) Close() error {
+	return s.client.Close()
+}
+
+// Subscribe watches the grants prefix and pushes the raw grant key on
+// ch whenever Allow or Revoke fires, anywhere in the cluster, so
+// downstream services can invalidate permission caches without
+// polling. Subscribe blocks until ctx is cancelled or the watch
+// channel closes.
+func (s *etcdStore) Subscribe(ctx context.Context, ch chan<- string) error {
+	watch := s.client.Watch(ctx, etcdGrantPrefix, clientv3.WithPrefix())
+	for resp := range watch {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range resp.Events {
+			if s.debug {
+				log.Printf("pgmanager: etcd watch event: %s %q", ev.Type, ev.Kv.Key)
+			}
+			select {
+			case ch <- string(ev.Kv.Key):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,33 @@
+package pgmanager
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchExcludesExpiredGrants(t *testing.T) {
+	if err := Connect("memory", ""); err != nil {
+		t.Fatalf("connect : err [%s]", err)
+	}
+	owner := fakeIdentity{name: "alice"}
+	grantee := fakeIdentity{name: "bob"}
+
+	if err := DefaultManager.Allow(owner, "read", "catalog", "bob", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("Allow (expired) : err [%s]", err)
+	}
+	if err := DefaultManager.Allow(owner, "write", "catalog", "bob", time.Time{}); err != nil {
+		t.Fatalf("Allow (never expires) : err [%s]", err)
+	}
+
+	var buf strings.Builder
+	if err := DefaultManager.Search(grantee, &buf, ""); err != nil {
+		t.Fatalf("Search : err [%s]", err)
+	}
+	if strings.Contains(buf.String(), "read:catalog") {
+		t.Errorf("Search : expired grant still authorizes: %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "write:catalog") {
+		t.Errorf("Search : never-expiring grant missing: %q", buf.String())
+	}
+}
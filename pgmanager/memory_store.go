@@ -0,0 +1,219 @@
+package pgmanager
+
+import (
+	"context"
+	"sync"
+)
+
+func init() {
+	registerDriver("memory", newMemoryStore)
+}
+
+// memoryStore is an in-process store backend with no persistence,
+// useful for tests and for single-process deployments that don't need
+// Manager state to survive a restart.
+type memoryStore struct {
+	mu       sync.Mutex
+	users    map[string]User
+	roles    map[string]Role
+	sessions map[string]Session
+	// sessionOrder tracks session IDs in creation order so that, when
+	// capacity caps sessions, the oldest one can be found and evicted
+	// without a scan proportional to id space.
+	sessionOrder []string
+	capacity     int
+	grants       []Grant
+	robots       map[string]Robot
+}
+
+func newMemoryStore(dsn string, opts *options) (store, error) {
+	return &memoryStore{
+		users:    make(map[string]User),
+		roles:    make(map[string]Role),
+		sessions: make(map[string]Session),
+		robots:   make(map[string]Robot),
+		capacity: opts.memoryCapacity,
+	}, nil
+}
+
+func (s *memoryStore) CreateUser(ctx context.Context, u User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[u.ID] = u
+	return nil
+}
+
+func (s *memoryStore) GetUser(ctx context.Context, id string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (s *memoryStore) DeleteUser(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, id)
+	return nil
+}
+
+func (s *memoryStore) CreateRole(ctx context.Context, r Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roles[r.ID] = r
+	return nil
+}
+
+func (s *memoryStore) GetRole(ctx context.Context, id string) (Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.roles[id]
+	if !ok {
+		return Role{}, ErrNotFound
+	}
+	return r, nil
+}
+
+func (s *memoryStore) DeleteRole(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.roles, id)
+	return nil
+}
+
+// CreateSession stores sess and, if the store was constructed with
+// WithMemoryCapacity(n > 0), evicts the oldest session(s) needed to
+// keep the store at or under n afterward.
+func (s *memoryStore) CreateSession(ctx context.Context, sess Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.sessions[sess.ID]; !exists {
+		s.sessionOrder = append(s.sessionOrder, sess.ID)
+	}
+	s.sessions[sess.ID] = sess
+	for s.capacity > 0 && len(s.sessions) > s.capacity {
+		oldest := s.sessionOrder[0]
+		s.sessionOrder = s.sessionOrder[1:]
+		delete(s.sessions, oldest)
+	}
+	return nil
+}
+
+func (s *memoryStore) GetSession(ctx context.Context, id string) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return Session{}, ErrNotFound
+	}
+	return sess, nil
+}
+
+func (s *memoryStore) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[id]; !ok {
+		return nil
+	}
+	delete(s.sessions, id)
+	for i, sid := range s.sessionOrder {
+		if sid == id {
+			s.sessionOrder = append(s.sessionOrder[:i], s.sessionOrder[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) CreateGrant(ctx context.Context, g Grant) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grants = append(s.grants, g)
+	return nil
+}
+
+// GetGrants returns grantee's grants, filtering out any whose
+// ExpiresAt has passed; see grantExpired.
+func (s *memoryStore) GetGrants(ctx context.Context, grantee string) ([]Grant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Grant
+	for _, g := range s.grants {
+		if g.Dst == grantee && !grantExpired(g.ExpiresAt) {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryStore) DeleteGrant(ctx context.Context, owner, dst, permission string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.grants[:0]
+	for _, g := range s.grants {
+		if g.Owner == owner && g.Dst == dst && g.Permission == permission {
+			continue
+		}
+		kept = append(kept, g)
+	}
+	s.grants = kept
+	return nil
+}
+
+func (s *memoryStore) CreateRobot(ctx context.Context, r Robot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.robots[r.ID] = r
+	return nil
+}
+
+func (s *memoryStore) GetRobotByToken(ctx context.Context, token string) (Robot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.robots {
+		if r.Token == token {
+			return r, nil
+		}
+	}
+	return Robot{}, ErrNotFound
+}
+
+func (s *memoryStore) GetRobotsByOwner(ctx context.Context, owner string) ([]Robot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Robot
+	for _, r := range s.robots {
+		if r.OwnerID == owner {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryStore) DisableRobot(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.robots[id]
+	if !ok {
+		return ErrNotFound
+	}
+	r.IsDisabled = true
+	s.robots[id] = r
+	return nil
+}
+
+// WithTx implements store. The in-memory backend has no native
+// transactions and each CRUD method already locks for its own
+// duration, so WithTx only groups fn's calls logically; it gives no
+// isolation from concurrent writers and no rollback. Of the built-in
+// backends, only sqlite3 runs fn in a real transaction.
+func (s *memoryStore) WithTx(ctx context.Context, fn func(tx store) error) error {
+	return fn(s)
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package pgmanager
+
+import (
+	"time"
+
+	"github.com/AthulMuralidhar/qra"
+)
+
+// User is a row of the users table.
+type User struct {
+	ID           string
+	PasswordHash string
+}
+
+// Role is a row of the roles table.
+type Role struct {
+	ID   string
+	Name string
+}
+
+// Session is a row of the sessions table, one per Authenticate call
+// until the matching Close.
+type Session struct {
+	ID        string
+	UserID    string
+	CreatedAt time.Time
+}
+
+// Grant is a permission-grant row: Owner gave Dst permission Permission
+// over Resource, optionally expiring at ExpiresAt.
+type Grant struct {
+	Owner      string
+	Dst        string
+	Permission string
+	Resource   string
+	ExpiresAt  time.Time
+}
+
+// Robot is a row of the robots table: a scoped, non-human credential
+// minted by Manager.AllowRobot on behalf of OwnerID. Disabling a robot
+// (IsDisabled) revokes its access without deleting its audit trail.
+type Robot struct {
+	ID          string
+	OwnerID     string
+	Token       string
+	IsDisabled  bool
+	Permissions []qra.PermissionGrant
+}
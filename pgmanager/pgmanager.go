@@ -0,0 +1,260 @@
+// MIT License
+//
+// Copyright (c) 2016 Angel Del Castillo
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package pgmanager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/AthulMuralidhar/qra"
+)
+
+var (
+	// ErrAuthenticationFailed is returned by Manager.Authenticate when
+	// the password does not match the stored user.
+	ErrAuthenticationFailed = fmt.Errorf("pgmanager: authentication failed")
+
+	// DefaultManager is the Manager configured by the most recent
+	// successful call to Connect.
+	DefaultManager *Manager
+)
+
+// Manager is pgmanager's implementation of qra.Authentication,
+// qra.Designation and qra.RuleSearcher, backed by a pluggable store.
+// Build one with Connect.
+type Manager struct {
+	store store
+}
+
+// Connect selects the store backend named by driver ("sqlite3",
+// "etcd" or "memory"), opens it against dsn and opts, and installs it
+// as DefaultManager. Backends register themselves under their driver
+// name via registerDriver, which is unexported, so only backends
+// inside this package can be selected.
+func Connect(driver, dsn string, opts ...Option) error {
+	cfg := defaultOptions()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	f, ok := drivers[driver]
+	if !ok {
+		return fmt.Errorf("pgmanager: unknown driver %q", driver)
+	}
+
+	s, err := f(dsn, cfg)
+	if err != nil {
+		return err
+	}
+
+	DefaultManager = &Manager{store: s}
+	return nil
+}
+
+// Authenticate implements qra.Authentication. It checks password
+// against the stored hash for ctx.Me(), and on success creates a
+// session row and writes its ID into dst, which must be a *string.
+func (m *Manager) Authenticate(ctx qra.Identity, password string, dst interface{}) error {
+	user, err := m.store.GetUser(context.Background(), ctx.Me())
+	if err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return ErrAuthenticationFailed
+	}
+
+	session := Session{ID: newSessionID(), UserID: user.ID, CreatedAt: time.Now()}
+	if err := m.store.CreateSession(context.Background(), session); err != nil {
+		return err
+	}
+
+	out, ok := dst.(*string)
+	if !ok {
+		return fmt.Errorf("pgmanager: unsupported Authenticate destination %T", dst)
+	}
+	*out = session.ID
+	return nil
+}
+
+// Close implements qra.Authentication. It deletes the session row
+// carried by ctx.
+func (m *Manager) Close(ctx qra.Identity) error {
+	var sessionID string
+	if err := ctx.Session(&sessionID); err != nil {
+		return err
+	}
+	return m.store.DeleteSession(context.Background(), sessionID)
+}
+
+// Search implements qra.Designation, writing one "permission:resource"
+// line per Grant held by ctx that matches filter.
+func (m *Manager) Search(ctx qra.Identity, writer io.Writer, filter string) error {
+	grants, err := m.store.GetGrants(context.Background(), ctx.Me())
+	if err != nil {
+		return err
+	}
+	for _, g := range grants {
+		if filter != "" && filter != g.Permission+":"+g.Resource {
+			continue
+		}
+		if _, err := fmt.Fprintf(writer, "%s:%s\n", g.Permission, g.Resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchRules implements qra.RuleSearcher, returning the Grants held
+// by ctx that match filter, as Search does, as PolicyRules directly so
+// callers can skip parsing Search's lines.
+func (m *Manager) SearchRules(ctx qra.Identity, filter string) ([]*qra.PolicyRule, error) {
+	grants, err := m.store.GetGrants(context.Background(), ctx.Me())
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*qra.PolicyRule, 0, len(grants))
+	for _, g := range grants {
+		if filter != "" && filter != g.Permission+":"+g.Resource {
+			continue
+		}
+		rules = append(rules, &qra.PolicyRule{
+			Verbs:     []string{g.Permission},
+			Resources: []string{g.Resource},
+		})
+	}
+	return rules, nil
+}
+
+// Allow implements qra.Designation, recording that ctx grants dst
+// permission over resource until expiresAt.
+func (m *Manager) Allow(ctx qra.Identity, permission, resource, dst string, expiresAt time.Time) error {
+	return m.store.CreateGrant(context.Background(), Grant{
+		Owner:      ctx.Me(),
+		Dst:        dst,
+		Permission: permission,
+		Resource:   resource,
+		ExpiresAt:  expiresAt,
+	})
+}
+
+// Revoke implements qra.Designation, removing a permission ctx
+// previously granted to dst. It also disables any robot dst has
+// minted whose scope included permission, since that robot's
+// authority traced back to the grant being revoked here.
+func (m *Manager) Revoke(ctx qra.Identity, permission, dst string) error {
+	if err := m.store.DeleteGrant(context.Background(), ctx.Me(), dst, permission); err != nil {
+		return err
+	}
+
+	robots, err := m.store.GetRobotsByOwner(context.Background(), dst)
+	if err != nil {
+		return err
+	}
+	for _, r := range robots {
+		if r.IsDisabled {
+			continue
+		}
+		for _, p := range r.Permissions {
+			if p.Permission == permission {
+				if err := m.store.DisableRobot(context.Background(), r.ID); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// AllowRobot implements qra.RobotDesignation. It resolves ctx's own
+// effective PolicyRules via a qra.RuleResolver and rejects spec if it
+// requests any permission/resource pair ctx does not itself hold,
+// then mints an opaque bearer token for the new robot. The scope
+// check below leaves Attributes.APIGroup unset because SearchRules
+// doesn't populate PolicyRule.APIGroups either; this depends on
+// qra.RuleAllows/APIGroupMatches treating an empty APIGroups as
+// unscoped rather than unmatchable.
+func (m *Manager) AllowRobot(ctx qra.Identity, spec qra.RobotSpec) (string, error) {
+	resolver := qra.NewRuleResolver(m)
+	rules, err := resolver.RulesFor(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	for _, grant := range spec.Permissions {
+		attrs := qra.Attributes{Verb: grant.Permission, Resource: grant.Resource}
+		allowed := false
+		for _, rule := range rules {
+			if qra.RuleAllows(rule, attrs) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "", fmt.Errorf("pgmanager: robot scope %s:%s exceeds %s's own permissions",
+				grant.Permission, grant.Resource, ctx.Me())
+		}
+	}
+
+	robot := Robot{
+		ID:          newSessionID(),
+		OwnerID:     ctx.Me(),
+		Token:       newSessionID(),
+		Permissions: spec.Permissions,
+	}
+	if err := m.store.CreateRobot(context.Background(), robot); err != nil {
+		return "", err
+	}
+	return robot.Token, nil
+}
+
+// ErrSubscribeUnsupported is returned by Manager.Subscribe when the
+// configured backend has no live-notification support (only the etcd
+// backend does).
+var ErrSubscribeUnsupported = fmt.Errorf("pgmanager: backend does not support Subscribe")
+
+// Subscribe pushes the key of every changed grant onto ch as Allow or
+// Revoke fire, anywhere in the cluster, so callers can invalidate
+// permission caches instead of polling Search. It blocks until ctx is
+// cancelled, and returns ErrSubscribeUnsupported on backends (sqlite3,
+// memory) that have no notification mechanism to offer.
+func (m *Manager) Subscribe(ctx context.Context, ch chan<- string) error {
+	sub, ok := m.store.(subscriber)
+	if !ok {
+		return ErrSubscribeUnsupported
+	}
+	return sub.Subscribe(ctx, ch)
+}
+
+func newSessionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
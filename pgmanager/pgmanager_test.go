@@ -30,8 +30,22 @@ package pgmanager
 import "testing"
 
 func TestConnect(t *testing.T) {
-	err := Connect("sqlite3", "", false)
+	err := Connect("sqlite3", "")
 	if err != nil {
 		t.Errorf("connect : err [%s]", err)
 	}
 }
+
+func TestConnectMemory(t *testing.T) {
+	err := Connect("memory", "")
+	if err != nil {
+		t.Errorf("connect : err [%s]", err)
+	}
+}
+
+func TestConnectUnknownDriver(t *testing.T) {
+	err := Connect("no-such-driver", "")
+	if err == nil {
+		t.Error("connect : want error for unknown driver, got nil")
+	}
+}
@@ -0,0 +1,71 @@
+package pgmanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AthulMuralidhar/qra"
+)
+
+// RobotIdentity adapts a robot's bearer token, as returned by
+// Manager.AllowRobot, into a qra.Identity. Callers typically construct
+// one per incoming request from the presented credential and resolve
+// it against the same Manager that minted the robot.
+type RobotIdentity struct {
+	token   string
+	manager *Manager
+	robot   *Robot
+}
+
+// NewRobotIdentity returns a RobotIdentity for token, resolved lazily
+// against manager's store the first time Me or Session is called.
+func NewRobotIdentity(token string, manager *Manager) *RobotIdentity {
+	return &RobotIdentity{token: token, manager: manager}
+}
+
+// Kind implements qra.Kinded, reporting that a RobotIdentity is always
+// a robot rather than a human user.
+func (r *RobotIdentity) Kind() qra.IdentityKind {
+	return qra.KindRobot
+}
+
+// Me returns the owning user's ID, resolving and caching the
+// underlying Robot row if this is the first call.
+func (r *RobotIdentity) Me() string {
+	robot, err := r.resolve()
+	if err != nil {
+		return ""
+	}
+	return robot.OwnerID
+}
+
+// Session writes the resolved Robot into dst, which must be a
+// *pgmanager.Robot. It returns an error if the robot has been
+// disabled, per the cascading-revoke behavior of Manager.Revoke.
+func (r *RobotIdentity) Session(dst interface{}) error {
+	robot, err := r.resolve()
+	if err != nil {
+		return err
+	}
+	if robot.IsDisabled {
+		return fmt.Errorf("pgmanager: robot %s is disabled", robot.ID)
+	}
+	out, ok := dst.(*Robot)
+	if !ok {
+		return fmt.Errorf("pgmanager: unsupported Session destination %T", dst)
+	}
+	*out = *robot
+	return nil
+}
+
+func (r *RobotIdentity) resolve() (*Robot, error) {
+	if r.robot != nil {
+		return r.robot, nil
+	}
+	robot, err := r.manager.store.GetRobotByToken(context.Background(), r.token)
+	if err != nil {
+		return nil, err
+	}
+	r.robot = &robot
+	return r.robot, nil
+}
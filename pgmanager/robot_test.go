@@ -0,0 +1,57 @@
+package pgmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AthulMuralidhar/qra"
+)
+
+type fakeIdentity struct {
+	name string
+}
+
+func (f fakeIdentity) Me() string { return f.name }
+
+func (f fakeIdentity) Session(dst interface{}) error { return nil }
+
+func TestAllowRobotWithinOwnerScope(t *testing.T) {
+	if err := Connect("memory", ""); err != nil {
+		t.Fatalf("connect : err [%s]", err)
+	}
+	owner := fakeIdentity{name: "alice"}
+
+	if err := DefaultManager.Allow(owner, "read", "catalog", "alice", time.Time{}); err != nil {
+		t.Fatalf("Allow : err [%s]", err)
+	}
+
+	token, err := DefaultManager.AllowRobot(owner, qra.RobotSpec{
+		Name: "ci-bot",
+		Permissions: []qra.PermissionGrant{
+			{Permission: "read", Resource: "catalog"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AllowRobot : err [%s]", err)
+	}
+	if token == "" {
+		t.Fatal("AllowRobot : empty token")
+	}
+}
+
+func TestAllowRobotRejectsOutOfScopePermission(t *testing.T) {
+	if err := Connect("memory", ""); err != nil {
+		t.Fatalf("connect : err [%s]", err)
+	}
+	owner := fakeIdentity{name: "bob"}
+
+	_, err := DefaultManager.AllowRobot(owner, qra.RobotSpec{
+		Name: "ci-bot",
+		Permissions: []qra.PermissionGrant{
+			{Permission: "write", Resource: "catalog"},
+		},
+	})
+	if err == nil {
+		t.Error("AllowRobot : want error for out-of-scope permission, got nil")
+	}
+}
@@ -0,0 +1,293 @@
+package pgmanager
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	registerDriver("sqlite3", newSQLiteStore)
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS users (
+	id TEXT PRIMARY KEY,
+	password_hash TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS roles (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS sessions (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS grants (
+	owner TEXT NOT NULL,
+	dst TEXT NOT NULL,
+	permission TEXT NOT NULL,
+	resource TEXT NOT NULL,
+	expires_at INTEGER NOT NULL,
+	PRIMARY KEY (owner, dst, permission, resource)
+);
+CREATE TABLE IF NOT EXISTS robots (
+	id TEXT PRIMARY KEY,
+	owner_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	token TEXT NOT NULL UNIQUE,
+	is_disabled INTEGER NOT NULL DEFAULT 0,
+	permissions TEXT NOT NULL
+);
+`
+
+// sqlExecutor is satisfied by both *sql.DB and *sql.Tx, so sqliteStore
+// can run its CRUD methods unchanged whether or not it's scoped to a
+// transaction.
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// sqliteStore is the original store backend, a thin wrapper over
+// database/sql and the mattn/go-sqlite3 driver.
+type sqliteStore struct {
+	db    *sql.DB // non-nil only for the top-level (non-transaction) store
+	exec  sqlExecutor
+	debug bool
+}
+
+func newSQLiteStore(dsn string, opts *options) (store, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// Foreign keys are off by default in sqlite3; turn them on so the
+	// robots.owner_id cascade actually fires. The PRAGMA only affects
+	// the connection it runs on, so the pool is held to that one.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON;"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqliteStore{db: db, exec: db, debug: opts.debug}, nil
+}
+
+// logQuery logs query and its args when the store was constructed with
+// WithDebug(true); it is a no-op otherwise.
+func (s *sqliteStore) logQuery(query string, args ...interface{}) {
+	if s.debug {
+		log.Printf("pgmanager: sqlite query: %s %v", query, args)
+	}
+}
+
+func (s *sqliteStore) CreateUser(ctx context.Context, u User) error {
+	s.logQuery(`INSERT INTO users (id, password_hash) VALUES (?, ?)`, u.ID, u.PasswordHash)
+	_, err := s.exec.ExecContext(ctx,
+		`INSERT INTO users (id, password_hash) VALUES (?, ?)`, u.ID, u.PasswordHash)
+	return err
+}
+
+func (s *sqliteStore) GetUser(ctx context.Context, id string) (User, error) {
+	var u User
+	s.logQuery(`SELECT id, password_hash FROM users WHERE id = ?`, id)
+	row := s.exec.QueryRowContext(ctx, `SELECT id, password_hash FROM users WHERE id = ?`, id)
+	if err := row.Scan(&u.ID, &u.PasswordHash); err == sql.ErrNoRows {
+		return User{}, ErrNotFound
+	} else if err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+func (s *sqliteStore) DeleteUser(ctx context.Context, id string) error {
+	s.logQuery(`DELETE FROM users WHERE id = ?`, id)
+	_, err := s.exec.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+	return err
+}
+
+func (s *sqliteStore) CreateRole(ctx context.Context, r Role) error {
+	s.logQuery(`INSERT INTO roles (id, name) VALUES (?, ?)`, r.ID, r.Name)
+	_, err := s.exec.ExecContext(ctx, `INSERT INTO roles (id, name) VALUES (?, ?)`, r.ID, r.Name)
+	return err
+}
+
+func (s *sqliteStore) GetRole(ctx context.Context, id string) (Role, error) {
+	var r Role
+	s.logQuery(`SELECT id, name FROM roles WHERE id = ?`, id)
+	row := s.exec.QueryRowContext(ctx, `SELECT id, name FROM roles WHERE id = ?`, id)
+	if err := row.Scan(&r.ID, &r.Name); err == sql.ErrNoRows {
+		return Role{}, ErrNotFound
+	} else if err != nil {
+		return Role{}, err
+	}
+	return r, nil
+}
+
+func (s *sqliteStore) DeleteRole(ctx context.Context, id string) error {
+	s.logQuery(`DELETE FROM roles WHERE id = ?`, id)
+	_, err := s.exec.ExecContext(ctx, `DELETE FROM roles WHERE id = ?`, id)
+	return err
+}
+
+func (s *sqliteStore) CreateSession(ctx context.Context, sess Session) error {
+	s.logQuery(`INSERT INTO sessions (id, user_id, created_at) VALUES (?, ?, ?)`,
+		sess.ID, sess.UserID, sess.CreatedAt.Unix())
+	_, err := s.exec.ExecContext(ctx,
+		`INSERT INTO sessions (id, user_id, created_at) VALUES (?, ?, ?)`,
+		sess.ID, sess.UserID, sess.CreatedAt.Unix())
+	return err
+}
+
+func (s *sqliteStore) GetSession(ctx context.Context, id string) (Session, error) {
+	var sess Session
+	var createdAt int64
+	s.logQuery(`SELECT id, user_id, created_at FROM sessions WHERE id = ?`, id)
+	row := s.exec.QueryRowContext(ctx, `SELECT id, user_id, created_at FROM sessions WHERE id = ?`, id)
+	if err := row.Scan(&sess.ID, &sess.UserID, &createdAt); err == sql.ErrNoRows {
+		return Session{}, ErrNotFound
+	} else if err != nil {
+		return Session{}, err
+	}
+	sess.CreatedAt = time.Unix(createdAt, 0)
+	return sess, nil
+}
+
+func (s *sqliteStore) DeleteSession(ctx context.Context, id string) error {
+	s.logQuery(`DELETE FROM sessions WHERE id = ?`, id)
+	_, err := s.exec.ExecContext(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+	return err
+}
+
+func (s *sqliteStore) CreateGrant(ctx context.Context, g Grant) error {
+	s.logQuery(`INSERT INTO grants (owner, dst, permission, resource, expires_at) VALUES (?, ?, ?, ?, ?)`,
+		g.Owner, g.Dst, g.Permission, g.Resource, g.ExpiresAt.Unix())
+	_, err := s.exec.ExecContext(ctx,
+		`INSERT INTO grants (owner, dst, permission, resource, expires_at) VALUES (?, ?, ?, ?, ?)`,
+		g.Owner, g.Dst, g.Permission, g.Resource, g.ExpiresAt.Unix())
+	return err
+}
+
+// GetGrants returns grantee's grants, filtering out any whose
+// ExpiresAt has passed; see grantExpired.
+func (s *sqliteStore) GetGrants(ctx context.Context, grantee string) ([]Grant, error) {
+	s.logQuery(`SELECT owner, dst, permission, resource, expires_at FROM grants WHERE dst = ?`, grantee)
+	rows, err := s.exec.QueryContext(ctx,
+		`SELECT owner, dst, permission, resource, expires_at FROM grants WHERE dst = ?`, grantee)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Grant
+	for rows.Next() {
+		var g Grant
+		var expiresAt int64
+		if err := rows.Scan(&g.Owner, &g.Dst, &g.Permission, &g.Resource, &expiresAt); err != nil {
+			return nil, err
+		}
+		g.ExpiresAt = time.Unix(expiresAt, 0)
+		if grantExpired(g.ExpiresAt) {
+			continue
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+func (s *sqliteStore) DeleteGrant(ctx context.Context, owner, dst, permission string) error {
+	s.logQuery(`DELETE FROM grants WHERE owner = ? AND dst = ? AND permission = ?`, owner, dst, permission)
+	_, err := s.exec.ExecContext(ctx,
+		`DELETE FROM grants WHERE owner = ? AND dst = ? AND permission = ?`, owner, dst, permission)
+	return err
+}
+
+func (s *sqliteStore) CreateRobot(ctx context.Context, r Robot) error {
+	perms, err := json.Marshal(r.Permissions)
+	if err != nil {
+		return err
+	}
+	s.logQuery(`INSERT INTO robots (id, owner_id, token, is_disabled, permissions) VALUES (?, ?, ?, ?, ?)`,
+		r.ID, r.OwnerID, r.Token, r.IsDisabled, string(perms))
+	_, err = s.exec.ExecContext(ctx,
+		`INSERT INTO robots (id, owner_id, token, is_disabled, permissions) VALUES (?, ?, ?, ?, ?)`,
+		r.ID, r.OwnerID, r.Token, r.IsDisabled, string(perms))
+	return err
+}
+
+func (s *sqliteStore) scanRobot(row *sql.Row) (Robot, error) {
+	var r Robot
+	var perms string
+	if err := row.Scan(&r.ID, &r.OwnerID, &r.Token, &r.IsDisabled, &perms); err == sql.ErrNoRows {
+		return Robot{}, ErrNotFound
+	} else if err != nil {
+		return Robot{}, err
+	}
+	if err := json.Unmarshal([]byte(perms), &r.Permissions); err != nil {
+		return Robot{}, err
+	}
+	return r, nil
+}
+
+func (s *sqliteStore) GetRobotByToken(ctx context.Context, token string) (Robot, error) {
+	s.logQuery(`SELECT id, owner_id, token, is_disabled, permissions FROM robots WHERE token = ?`, token)
+	row := s.exec.QueryRowContext(ctx,
+		`SELECT id, owner_id, token, is_disabled, permissions FROM robots WHERE token = ?`, token)
+	return s.scanRobot(row)
+}
+
+func (s *sqliteStore) GetRobotsByOwner(ctx context.Context, owner string) ([]Robot, error) {
+	s.logQuery(`SELECT id, owner_id, token, is_disabled, permissions FROM robots WHERE owner_id = ?`, owner)
+	rows, err := s.exec.QueryContext(ctx,
+		`SELECT id, owner_id, token, is_disabled, permissions FROM robots WHERE owner_id = ?`, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Robot
+	for rows.Next() {
+		var r Robot
+		var perms string
+		if err := rows.Scan(&r.ID, &r.OwnerID, &r.Token, &r.IsDisabled, &perms); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(perms), &r.Permissions); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func (s *sqliteStore) DisableRobot(ctx context.Context, id string) error {
+	s.logQuery(`UPDATE robots SET is_disabled = 1 WHERE id = ?`, id)
+	_, err := s.exec.ExecContext(ctx, `UPDATE robots SET is_disabled = 1 WHERE id = ?`, id)
+	return err
+}
+
+// WithTx implements store using a real database/sql transaction.
+func (s *sqliteStore) WithTx(ctx context.Context, fn func(tx store) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&sqliteStore{exec: tx, debug: s.debug}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
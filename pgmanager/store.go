@@ -0,0 +1,112 @@
+package pgmanager
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by a store when a CRUD lookup finds nothing.
+var ErrNotFound = errors.New("pgmanager: not found")
+
+// grantExpired reports whether a Grant with the given ExpiresAt should
+// no longer authorize its holder. A zero ExpiresAt means "never
+// expires", matching the convention the etcd backend uses for leases.
+func grantExpired(expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && expiresAt.Before(time.Now())
+}
+
+// store is the persistence interface each pgmanager backend must
+// implement: CRUD for users, roles, sessions and permission-grants,
+// plus WithTx for operations that must apply atomically.
+type store interface {
+	CreateUser(ctx context.Context, u User) error
+	GetUser(ctx context.Context, id string) (User, error)
+	DeleteUser(ctx context.Context, id string) error
+
+	CreateRole(ctx context.Context, r Role) error
+	GetRole(ctx context.Context, id string) (Role, error)
+	DeleteRole(ctx context.Context, id string) error
+
+	CreateSession(ctx context.Context, s Session) error
+	GetSession(ctx context.Context, id string) (Session, error)
+	DeleteSession(ctx context.Context, id string) error
+
+	CreateGrant(ctx context.Context, g Grant) error
+	// GetGrants returns the Grants held by grantee, i.e. those whose
+	// Dst equals grantee, regardless of who granted them.
+	GetGrants(ctx context.Context, grantee string) ([]Grant, error)
+	DeleteGrant(ctx context.Context, owner, dst, permission string) error
+
+	CreateRobot(ctx context.Context, r Robot) error
+	GetRobotByToken(ctx context.Context, token string) (Robot, error)
+	GetRobotsByOwner(ctx context.Context, owner string) ([]Robot, error)
+	DisableRobot(ctx context.Context, id string) error
+
+	// WithTx runs fn with a store for operations that belong together.
+	// The sqlite3 backend scopes that store to a single transaction,
+	// committing if fn returns nil and rolling back otherwise. The
+	// memory and etcd backends run fn directly against themselves:
+	// fn's writes are neither isolated nor rolled back on error.
+	WithTx(ctx context.Context, fn func(tx store) error) error
+
+	// Close releases any resources (connections, watches) held by the
+	// store.
+	Close() error
+}
+
+// options configures a store constructed by Connect.
+type options struct {
+	debug bool
+
+	etcdDialTimeout time.Duration
+	memoryCapacity  int
+}
+
+func defaultOptions() *options {
+	return &options{
+		etcdDialTimeout: 5 * time.Second,
+		memoryCapacity:  0, // unbounded
+	}
+}
+
+// Option configures the store Connect constructs. See WithDebug,
+// WithEtcdDialTimeout and WithMemoryCapacity.
+type Option func(*options)
+
+// WithDebug enables verbose backend logging (query logging for the
+// sql backend, watch-event logging for the etcd backend).
+func WithDebug(debug bool) Option {
+	return func(o *options) { o.debug = debug }
+}
+
+// WithEtcdDialTimeout bounds how long the etcd backend waits to
+// establish its client connection during Connect. Ignored by other
+// backends.
+func WithEtcdDialTimeout(d time.Duration) Option {
+	return func(o *options) { o.etcdDialTimeout = d }
+}
+
+// WithMemoryCapacity bounds how many sessions the in-memory backend
+// keeps before evicting the oldest. Zero (the default) means
+// unbounded. Ignored by other backends.
+func WithMemoryCapacity(n int) Option {
+	return func(o *options) { o.memoryCapacity = n }
+}
+
+// subscriber is an optional extension of store, implemented by
+// backends (currently only etcd) that can push live notifications of
+// grant changes instead of making callers poll.
+type subscriber interface {
+	Subscribe(ctx context.Context, ch chan<- string) error
+}
+
+// factory constructs a store for the given dsn and options. Each
+// backend registers its factory in drivers from an init function.
+type factory func(dsn string, opts *options) (store, error)
+
+var drivers = map[string]factory{}
+
+func registerDriver(name string, f factory) {
+	drivers[name] = f
+}
@@ -48,6 +48,10 @@ var (
 type QRA struct {
 	Authentication           Authentication
 	DesignationAuthorization Designation
+
+	// evaluators are the Evaluators registered with RegisterEvaluator,
+	// consulted in registration order by Authorize.
+	evaluators []namedEvaluator
 }
 
 // New returns a new QRA struct.